@@ -0,0 +1,122 @@
+package interval
+
+import (
+	"bytes"
+	"cmp"
+	"net/netip"
+	"strings"
+)
+
+// Comparable provides a total order over Key values.
+type Comparable[Key any] interface {
+	// Compare returns a negative number if a < b, zero if a == b, and a
+	// positive number if a > b.
+	Compare(a, b Key) int
+}
+
+// Bucketer extends [Comparable] with what the hierarchical tree needs to
+// route keys without a hand-rolled encoding: a 4-bit bucket index ([0,16))
+// for a key at a given tree depth (0 being the root, immediately below the
+// most significant bits of the key).
+type Bucketer[Key any] interface {
+	Comparable[Key]
+
+	// Bucket returns the bucket, in [0, hierarchicalFanout), that key falls
+	// into at depth. Bucketer implementations should treat keys shorter than
+	// depth requires as padded with zero bits, so that every key eventually
+	// routes to a bucket at every depth.
+	Bucket(key Key, depth int) int
+}
+
+// Uint64Bucketer is the built-in [Bucketer] for uint64 keys: the bit-shift
+// scheme the tree has always used, extracting 4 bits per depth from the most
+// significant end.
+type Uint64Bucketer struct{}
+
+// Compare implements [Bucketer].
+func (Uint64Bucketer) Compare(a, b uint64) int {
+	return cmp.Compare(a, b)
+}
+
+// Bucket implements [Bucketer].
+func (Uint64Bucketer) Bucket(key uint64, depth int) int {
+	shift := 64 - branchingFactorPower*(depth+1)
+	if shift < 0 {
+		// Beyond the 64-bit key's precision, every remaining key is
+		// indistinguishable, so they all route the same way.
+		return 0
+	}
+
+	return int((key >> uint(shift)) & (hierarchicalFanout - 1))
+}
+
+// Int64Bucketer is the built-in [Bucketer] for signed integer keys. It biases
+// by 1<<63 so that two's-complement ordering matches the unsigned bit
+// pattern ordering [Uint64Bucketer] relies on, then buckets identically.
+type Int64Bucketer struct{}
+
+// Compare implements [Bucketer].
+func (Int64Bucketer) Compare(a, b int64) int {
+	return cmp.Compare(a, b)
+}
+
+// Bucket implements [Bucketer].
+func (Int64Bucketer) Bucket(key int64, depth int) int {
+	return Uint64Bucketer{}.Bucket(uint64(key)^(1<<63), depth)
+}
+
+// BytesBucketer is the built-in [Bucketer] for []byte keys, bucketing on one
+// nibble of the slice per depth (most significant nibble of the first byte
+// first). Keys shorter than depth requires are treated as padded with zero
+// bytes.
+type BytesBucketer struct{}
+
+// Compare implements [Bucketer].
+func (BytesBucketer) Compare(a, b []byte) int {
+	return bytes.Compare(a, b)
+}
+
+// Bucket implements [Bucketer].
+func (BytesBucketer) Bucket(key []byte, depth int) int {
+	byteIndex := depth / 2
+	if byteIndex >= len(key) {
+		return 0
+	}
+
+	if depth%2 == 0 {
+		return int(key[byteIndex] >> 4)
+	}
+
+	return int(key[byteIndex] & 0xF)
+}
+
+// StringBucketer is the built-in [Bucketer] for string keys, bucketing
+// lexicographically one nibble at a time; see [BytesBucketer].
+type StringBucketer struct{}
+
+// Compare implements [Bucketer].
+func (StringBucketer) Compare(a, b string) int {
+	return strings.Compare(a, b)
+}
+
+// Bucket implements [Bucketer].
+func (StringBucketer) Bucket(key string, depth int) int {
+	return BytesBucketer{}.Bucket([]byte(key), depth)
+}
+
+// NetipAddrBucketer is the built-in [Bucketer] for [netip.Addr] keys,
+// bucketing one nibble of the 128-bit representation per depth (IPv4
+// addresses are bucketed in their 4-in-6 form; see [netip.Addr.As16]).
+type NetipAddrBucketer struct{}
+
+// Compare implements [Bucketer].
+func (NetipAddrBucketer) Compare(a, b netip.Addr) int {
+	return a.Compare(b)
+}
+
+// Bucket implements [Bucketer].
+func (NetipAddrBucketer) Bucket(key netip.Addr, depth int) int {
+	addrBytes := key.As16()
+
+	return BytesBucketer{}.Bucket(addrBytes[:], depth)
+}
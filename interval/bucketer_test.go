@@ -0,0 +1,318 @@
+package interval_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"net/netip"
+	"testing"
+
+	"github.com/crystalix007/multi-binned-intervals/interval"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInt64Bucketer_Compare(t *testing.T) {
+	t.Parallel()
+
+	bucketer := interval.Int64Bucketer{}
+
+	require.Negative(t, bucketer.Compare(-5, 5))
+	require.Positive(t, bucketer.Compare(5, -5))
+	require.Zero(t, bucketer.Compare(5, 5))
+}
+
+func TestInt64Bucketer_Bucket(t *testing.T) {
+	t.Parallel()
+
+	bucketer := interval.Int64Bucketer{}
+
+	// Biasing by 1<<63 should put the most negative value in bucket 0, zero
+	// in the middle bucket, and the most positive value in the last bucket -
+	// matching the unsigned bit pattern ordering Uint64Bucketer relies on.
+	require.Equal(t, 0, bucketer.Bucket(math.MinInt64, 0))
+	require.Equal(t, 8, bucketer.Bucket(0, 0))
+	require.Equal(t, interval.HierarchicalFanout-1, bucketer.Bucket(math.MaxInt64, 0))
+
+	// Bucketing should be consistent with Compare: a lesser key should never
+	// land in a later bucket at the same depth.
+	require.LessOrEqual(t, bucketer.Bucket(-5, 0), bucketer.Bucket(5, 0))
+}
+
+func TestBytesBucketer_Compare(t *testing.T) {
+	t.Parallel()
+
+	bucketer := interval.BytesBucketer{}
+
+	require.Negative(t, bucketer.Compare([]byte("abc"), []byte("abd")))
+	require.Positive(t, bucketer.Compare([]byte("abd"), []byte("abc")))
+	require.Zero(t, bucketer.Compare([]byte("abc"), []byte("abc")))
+}
+
+func TestBytesBucketer_Bucket(t *testing.T) {
+	t.Parallel()
+
+	bucketer := interval.BytesBucketer{}
+
+	key := []byte{0xAB, 0xCD}
+
+	// One nibble per depth, most significant nibble of the first byte first.
+	require.Equal(t, 0xA, bucketer.Bucket(key, 0))
+	require.Equal(t, 0xB, bucketer.Bucket(key, 1))
+	require.Equal(t, 0xC, bucketer.Bucket(key, 2))
+	require.Equal(t, 0xD, bucketer.Bucket(key, 3))
+
+	// Depths beyond the key's length pad with zero bits.
+	require.Equal(t, 0, bucketer.Bucket(key, 4))
+	require.Equal(t, 0, bucketer.Bucket(key, 100))
+}
+
+func TestStringBucketer_Compare(t *testing.T) {
+	t.Parallel()
+
+	bucketer := interval.StringBucketer{}
+
+	require.Negative(t, bucketer.Compare("abc", "abd"))
+	require.Positive(t, bucketer.Compare("abd", "abc"))
+	require.Zero(t, bucketer.Compare("abc", "abc"))
+}
+
+func TestStringBucketer_Bucket(t *testing.T) {
+	t.Parallel()
+
+	bucketer := interval.StringBucketer{}
+
+	// Should bucket identically to BytesBucketer over the same bytes.
+	key := "\xAB\xCD"
+
+	require.Equal(t, 0xA, bucketer.Bucket(key, 0))
+	require.Equal(t, 0xB, bucketer.Bucket(key, 1))
+	require.Equal(t, 0xC, bucketer.Bucket(key, 2))
+	require.Equal(t, 0xD, bucketer.Bucket(key, 3))
+	require.Equal(t, 0, bucketer.Bucket(key, 4))
+}
+
+func TestNetipAddrBucketer_Compare(t *testing.T) {
+	t.Parallel()
+
+	bucketer := interval.NetipAddrBucketer{}
+
+	lesser := netip.MustParseAddr("10.0.0.1")
+	greater := netip.MustParseAddr("10.0.0.2")
+
+	require.Negative(t, bucketer.Compare(lesser, greater))
+	require.Positive(t, bucketer.Compare(greater, lesser))
+	require.Zero(t, bucketer.Compare(lesser, lesser))
+}
+
+func TestNetipAddrBucketer_Bucket(t *testing.T) {
+	t.Parallel()
+
+	bucketer := interval.NetipAddrBucketer{}
+
+	// Two addresses sharing every nibble but the last one of the IPv4
+	// payload should bucket identically everywhere except that final
+	// nibble.
+	a := netip.MustParseAddr("10.0.0.1")
+	b := netip.MustParseAddr("10.0.0.2")
+
+	lastNibbleDepth := 31
+
+	for depth := range lastNibbleDepth {
+		require.Equal(t, bucketer.Bucket(a, depth), bucketer.Bucket(b, depth), "depth %d", depth)
+	}
+
+	require.NotEqual(t, bucketer.Bucket(a, lastNibbleDepth), bucketer.Bucket(b, lastNibbleDepth))
+
+	// IPv4 addresses are bucketed in their 4-in-6 mapped form, so an IPv6
+	// address sharing no prefix with a mapped IPv4 address should diverge
+	// immediately.
+	v6 := netip.MustParseAddr("2001:db8::1")
+
+	require.NotEqual(t, bucketer.Bucket(a, 0), bucketer.Bucket(v6, 0))
+}
+
+// FuzzInt64Bucketer, FuzzBytesBucketer, FuzzStringBucketer, and
+// FuzzNetipAddrBucketer mirror FuzzTree for every non-uint64 Bucketer, via
+// the shared checkBucketerIntersections helper - this is exactly the kind of
+// coverage gap (a key-routing bug reachable only through a non-default
+// Bucketer) that let the straddling-bucket regression ship unnoticed.
+
+func FuzzInt64Bucketer(f *testing.F) {
+	f.Fuzz(func(t *testing.T, intervalCount uint16, endpoint1, endpoint2 int64) {
+		intervalBegin, intervalEnd := endpoint1, endpoint2
+		if intervalBegin > intervalEnd {
+			intervalBegin, intervalEnd = intervalEnd, intervalBegin
+		}
+
+		intervals := make([]interval.Interval[int64], intervalCount)
+
+		for i := range intervalCount {
+			a, b := rand.Int64(), rand.Int64()
+			if a > b {
+				a, b = b, a
+			}
+
+			intervals[i] = interval.Interval[int64]{Start: a, End: b}
+		}
+
+		checkBucketerIntersections(t, interval.Int64Bucketer{}, intervals, intervalBegin, intervalEnd)
+	})
+}
+
+func FuzzBytesBucketer(f *testing.F) {
+	f.Fuzz(func(t *testing.T, queryStart, queryEnd []byte, rawIntervals []byte) {
+		intervalBegin, intervalEnd := queryStart, queryEnd
+		if bytesGreater(intervalBegin, intervalEnd) {
+			intervalBegin, intervalEnd = intervalEnd, intervalBegin
+		}
+
+		parts := splitBytesEvenly(rawIntervals)
+
+		intervals := make([]interval.Interval[[]byte], 0, len(parts)/2)
+
+		for i := 0; i+1 < len(parts); i += 2 {
+			a, b := parts[i], parts[i+1]
+			if bytesGreater(a, b) {
+				a, b = b, a
+			}
+
+			intervals = append(intervals, interval.Interval[[]byte]{Start: a, End: b})
+		}
+
+		checkBucketerIntersections(t, interval.BytesBucketer{}, intervals, intervalBegin, intervalEnd)
+	})
+}
+
+func FuzzStringBucketer(f *testing.F) {
+	f.Fuzz(func(t *testing.T, queryStart, queryEnd string, rawIntervals string) {
+		intervalBegin, intervalEnd := queryStart, queryEnd
+		if intervalBegin > intervalEnd {
+			intervalBegin, intervalEnd = intervalEnd, intervalBegin
+		}
+
+		parts := splitEvenly(rawIntervals)
+
+		intervals := make([]interval.Interval[string], 0, len(parts)/2)
+
+		for i := 0; i+1 < len(parts); i += 2 {
+			a, b := parts[i], parts[i+1]
+			if a > b {
+				a, b = b, a
+			}
+
+			intervals = append(intervals, interval.Interval[string]{Start: a, End: b})
+		}
+
+		checkBucketerIntersections(t, interval.StringBucketer{}, intervals, intervalBegin, intervalEnd)
+	})
+}
+
+func FuzzNetipAddrBucketer(f *testing.F) {
+	f.Fuzz(func(t *testing.T, intervalCount uint16, rawA, rawB uint32) {
+		queryStart := netip.AddrFrom4([4]byte{byte(rawA >> 24), byte(rawA >> 16), byte(rawA >> 8), byte(rawA)})
+		queryEnd := netip.AddrFrom4([4]byte{byte(rawB >> 24), byte(rawB >> 16), byte(rawB >> 8), byte(rawB)})
+
+		if queryStart.Compare(queryEnd) > 0 {
+			queryStart, queryEnd = queryEnd, queryStart
+		}
+
+		intervals := make([]interval.Interval[netip.Addr], intervalCount)
+
+		for i := range intervalCount {
+			x, y := rand.Uint32(), rand.Uint32()
+
+			a := netip.AddrFrom4([4]byte{byte(x >> 24), byte(x >> 16), byte(x >> 8), byte(x)})
+			b := netip.AddrFrom4([4]byte{byte(y >> 24), byte(y >> 16), byte(y >> 8), byte(y)})
+
+			if a.Compare(b) > 0 {
+				a, b = b, a
+			}
+
+			intervals[i] = interval.Interval[netip.Addr]{Start: a, End: b}
+		}
+
+		checkBucketerIntersections(t, interval.NetipAddrBucketer{}, intervals, queryStart, queryEnd)
+	})
+}
+
+// checkBucketerIntersections builds a [interval.Tree] over bucketer from
+// intervals, and asserts that AllIntersections(queryStart, queryEnd) matches
+// a brute-force scan - the same check FuzzTree performs for uint64 keys, but
+// generic over Key so it exercises every Bucketer.
+func checkBucketerIntersections[Key any](
+	t *testing.T,
+	bucketer interval.Bucketer[Key],
+	intervals []interval.Interval[Key],
+	queryStart, queryEnd Key,
+) {
+	t.Helper()
+
+	tree := interval.NewOrdered[Key, int](bucketer)
+
+	for i, iv := range intervals {
+		tree.Add(iv, i)
+	}
+
+	actual, foundIntersections := tree.AllIntersections(queryStart, queryEnd)
+
+	var expected []int
+
+	for i, iv := range intervals {
+		if bucketer.Compare(queryEnd, iv.Start) < 0 || bucketer.Compare(iv.End, queryStart) < 0 {
+			continue
+		}
+
+		expected = append(expected, i)
+	}
+
+	require.Equal(t, len(expected) > 0, foundIntersections)
+	require.ElementsMatch(t, expected, actual)
+}
+
+func bytesGreater(a, b []byte) bool {
+	return interval.BytesBucketer{}.Compare(a, b) > 0
+}
+
+// splitBytesEvenly chops b into up to 8 roughly-equal, possibly-empty
+// pieces, so a single fuzzed byte slice can seed several candidate interval
+// endpoints.
+func splitBytesEvenly(b []byte) [][]byte {
+	const pieces = 8
+
+	if len(b) == 0 {
+		return make([][]byte, pieces)
+	}
+
+	chunk := max(len(b)/pieces, 1)
+
+	parts := make([][]byte, 0, pieces)
+
+	for i := 0; i < len(b); i += chunk {
+		end := min(i+chunk, len(b))
+
+		parts = append(parts, b[i:end])
+	}
+
+	return parts
+}
+
+// splitEvenly chops s into up to 8 roughly-equal, possibly-empty pieces, so a
+// single fuzzed string can seed several candidate interval endpoints.
+func splitEvenly(s string) []string {
+	const pieces = 8
+
+	if len(s) == 0 {
+		return make([]string, pieces)
+	}
+
+	chunk := max(len(s)/pieces, 1)
+
+	parts := make([]string, 0, pieces)
+
+	for i := 0; i < len(s); i += chunk {
+		end := min(i+chunk, len(s))
+
+		parts = append(parts, s[i:end])
+	}
+
+	return parts
+}
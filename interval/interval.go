@@ -1,30 +1,17 @@
 package interval
 
-import (
-	"iter"
-	"maps"
-	"math"
-	"slices"
-)
+import "iter"
 
 const (
 	// branchingFactorPower is the power that 2 is raised to in order to get the
 	// branching factor of the hierarchical interval tree.
 	//
 	// i.e. 4 -> 2^4 = 16
-	branchingFactorPower uint64 = 4
+	branchingFactorPower = 4
 
 	// hierarchicalFanout is the number of child elements in a single
 	// hierarchical node.
-	hierarchicalFanout uint64 = 1 << branchingFactorPower
-
-	// offsetMask is the mask used to extract the offset from the interval
-	// start and end.
-	offsetMask uint64 = 1<<branchingFactorPower - 1
-
-	// bucketMask is the mask used to extract the bucket from the interval start
-	// and end.
-	bucketMask uint64 = ^offsetMask
+	hierarchicalFanout = 1 << branchingFactorPower
 
 	// maxLeafFanout is the maximum number of intervals that a leaf node can
 	// store.
@@ -34,215 +21,405 @@ const (
 	maxLeafFanout = 16
 )
 
-// Interval represents the closed interval [Start, End].
-type Interval struct {
-	Start uint64
-	End   uint64
+// Interval represents the closed interval [Start, End] over an ordered key
+// type.
+type Interval[Key any] struct {
+	Start Key
+	End   Key
 }
 
-// Tree is a node in a hierarchical interval tree.
-type Tree[Value any] interface {
-	Add(interval Interval, value Value)
-	AllIntersections(start, end uint64) ([]Value, bool)
+// Tree is a node in a hierarchical interval tree, keyed by Key and storing
+// Value.
+type Tree[Key any, Value comparable] interface {
+	Add(interval Interval[Key], value Value)
+
+	// Remove deletes the entry matching both interval and value, reporting
+	// whether anything was removed.
+	Remove(interval Interval[Key], value Value) bool
+
+	// Update replaces the entry matching interval and oldValue with
+	// newValue, reporting whether a matching entry was found.
+	Update(interval Interval[Key], oldValue, newValue Value) bool
+
+	AllIntersections(start, end Key) ([]Value, bool)
+
+	// Intersections lazily yields every (interval, value) pair that
+	// intersects [start, end]. Stopping the iteration early avoids the cost
+	// of visiting the rest of the tree.
+	Intersections(start, end Key) iter.Seq2[Interval[Key], Value]
+
+	// Stab lazily yields every (interval, value) pair whose interval
+	// contains point.
+	Stab(point Key) iter.Seq2[Interval[Key], Value]
+
+	// Freeze builds an immutable, cache-friendly [StaticTree] from every
+	// interval currently stored in the tree.
+	Freeze() *StaticTree[Key, Value]
 }
 
 // tree is a hierarchical interval tree.
 //
 // It stores the intervals in a tree structure, and the values in a separate
 // slice.
-type tree[Value any] struct {
-	Root   node
-	Values []Value
+type tree[Key any, Value comparable] struct {
+	Bucketer Bucketer[Key]
+	Root     node[Key]
+	Values   []Value
 }
 
-// New creates a new interval tree.
-func New[Value any]() Tree[Value] {
-	return &tree[Value]{
-		Root: newHierarchicalNode(),
+// New creates a new interval tree over the built-in uint64 key space, using
+// the same bit-shift bucketing the tree has always used.
+//
+// It is a thin alias for [NewOrdered] with a [Uint64Bucketer], kept so that
+// existing callers of New don't need to change.
+func New[Value comparable]() Tree[uint64, Value] {
+	return NewOrdered[uint64, Value](Uint64Bucketer{})
+}
+
+// NewOrdered creates a new interval tree over keys of type Key, using
+// bucketer both to order keys and to route them through the hierarchical
+// tree.
+func NewOrdered[Key any, Value comparable](bucketer Bucketer[Key]) Tree[Key, Value] {
+	return &tree[Key, Value]{
+		Bucketer: bucketer,
+		Root:     newHierarchicalNode[Key](),
 	}
 }
 
 // Add inserts a new interval into the interval tree.
-func (t *tree[Value]) Add(interval Interval, value Value) {
+func (t *tree[Key, Value]) Add(interval Interval[Key], value Value) {
 	valuesIndex := len(t.Values)
 	t.Values = append(t.Values, value)
 
-	// Add the interval, using the new values index.
-	t.Root.Add(interval, valuesIndex)
+	// Add the interval, using the new values index. Both endpoints still
+	// constrain routing at the root.
+	t.Root.Add(t.Bucketer, interval, valuesIndex, 0, true, true)
 }
 
-// AllIntersections returns all values in the interval tree that intersect with
-// the given interval.
-func (t *tree[Value]) AllIntersections(start uint64, end uint64) ([]Value, bool) {
-	indices := t.Root.AllIntersections(start, end)
+// Remove deletes the entry matching both interval and value from every
+// bucket that Add would have routed it into, collapsing hierarchical nodes
+// back into a leaf once they become sparse. It reports whether anything was
+// removed.
+func (t *tree[Key, Value]) Remove(interval Interval[Key], value Value) bool {
+	target := -1
+
+	matches := func(valuesIndex int) bool {
+		// Once the target index is known, every other touched leaf must
+		// delete that same index, rather than some other entry that
+		// happens to share the same value.
+		if target != -1 {
+			return valuesIndex == target
+		}
+
+		if t.Values[valuesIndex] != value {
+			return false
+		}
+
+		target = valuesIndex
 
-	if len(indices) == 0 {
-		return nil, false
+		return true
 	}
 
-	values := make([]Value, 0, len(indices))
+	root, removed := t.Root.Remove(t.Bucketer, interval, matches, 0, true, true)
+	if !removed {
+		return false
+	}
 
-	for index := range indices {
-		values = append(values, t.Values[index])
+	// A root leaf that loses its last entry comes back as nil; replace it
+	// with an empty hierarchical node so later calls still have a root.
+	if root == nil {
+		root = newHierarchicalNode[Key]()
 	}
 
-	return values, true
-}
+	t.Root = root
+
+	var zero Value
 
-// valueIndices is a set of value indices.
-type valueIndices map[int]struct{}
+	t.Values[target] = zero
 
-// Merge merges the other value indices into this set.
-func (v *valueIndices) Merge(other valueIndices) {
-	for index := range other {
-		(*v)[index] = struct{}{}
+	return true
+}
+
+// Update replaces the entry matching interval and oldValue with newValue. It
+// is implemented as a paired [tree.Remove] and [tree.Add], so it reports
+// whether a matching entry was found and replaced.
+func (t *tree[Key, Value]) Update(interval Interval[Key], oldValue, newValue Value) bool {
+	if !t.Remove(interval, oldValue) {
+		return false
 	}
+
+	t.Add(interval, newValue)
+
+	return true
 }
 
-// All returns an iterator over the value indices.
-func (v valueIndices) All() iter.Seq[int] {
-	return func(yield func(int) bool) {
-		for index := range v {
-			if !yield(index) {
-				return
-			}
-		}
+// AllIntersections returns all values in the interval tree that intersect with
+// the given interval.
+func (t *tree[Key, Value]) AllIntersections(start Key, end Key) ([]Value, bool) {
+	var values []Value
 
-		return
+	for _, value := range t.Intersections(start, end) {
+		values = append(values, value)
 	}
+
+	return values, len(values) > 0
 }
 
-// Sorted returns the value indices in sorted order.
-func (v valueIndices) Sorted() []int {
-	indices := slices.Collect(maps.Keys(v))
+// Intersections lazily yields every (interval, value) pair that intersects
+// [start, end], walking the tree depth-first and stopping as soon as the
+// caller's range-over-func loop breaks.
+//
+// A single Add call can route its interval into more than one bucket (that's
+// what makes this a *multi*-binned interval tree), so the walk tracks which
+// value indices it has already yielded to avoid surfacing the same entry
+// twice. That bookkeeping grows only as far as the caller actually consumes
+// the sequence, rather than materializing the whole result up front.
+func (t *tree[Key, Value]) Intersections(start, end Key) iter.Seq2[Interval[Key], Value] {
+	return func(yield func(Interval[Key], Value) bool) {
+		var seen map[int]struct{}
+
+		t.Root.Intersections(t.Bucketer, start, end, 0, true, true, func(interval Interval[Key], valuesIndex int) bool {
+			if seen == nil {
+				seen = make(map[int]struct{})
+			} else if _, ok := seen[valuesIndex]; ok {
+				return true
+			}
 
-	slices.Sort(indices)
+			seen[valuesIndex] = struct{}{}
+
+			return yield(interval, t.Values[valuesIndex])
+		})
+	}
+}
 
-	return indices
+// Stab lazily yields every (interval, value) pair whose interval contains
+// point. It is a thin wrapper around [tree.Intersections].
+func (t *tree[Key, Value]) Stab(point Key) iter.Seq2[Interval[Key], Value] {
+	return t.Intersections(point, point)
 }
 
 // node is the interface that all node types in the interval tree implement.
-type node interface {
-	Add(interval Interval, valuesIndex int) node
-	AllIntersections(start, end uint64) valueIndices
+//
+// Add, Remove, and Intersections all take boundedStart and boundedEnd, which
+// report whether interval.Start/interval.End (or start/end, for
+// Intersections) still constrain which bucket to route into below depth.
+// They start true at the root. boundedStart goes false once a straddling
+// interval has been routed into any bucket after its start bucket at some
+// ancestor depth, and boundedEnd once it has been routed into any bucket
+// before its end bucket - from there down, that side of the interval lies
+// beyond the bucket's whole range, so it no longer narrows anything (in a
+// bucket strictly between the two, both sides go false). This mirrors the original
+// bit-shifted implementation's trick of resetting such a "middle" bucket's
+// sub-interval to {0, MaxUint64} before recursing, but without relying on Key
+// supporting arithmetic.
+type node[Key any] interface {
+	Add(bucketer Bucketer[Key], interval Interval[Key], valuesIndex int, depth int, boundedStart, boundedEnd bool) node[Key]
+
+	// Remove deletes the (interval, valuesIndex) pair for which matches
+	// reports true from every bucket interval routes into, reporting the
+	// (possibly collapsed) replacement node and whether anything was
+	// removed.
+	Remove(bucketer Bucketer[Key], interval Interval[Key], matches func(valuesIndex int) bool, depth int, boundedStart, boundedEnd bool) (node[Key], bool)
+
+	// Intersections calls yield for every (interval, valuesIndex) pair
+	// stored beneath this node that overlaps [start, end], stopping early
+	// if yield returns false. It returns false once a stop has propagated
+	// all the way back up.
+	Intersections(bucketer Bucketer[Key], start, end Key, depth int, boundedStart, boundedEnd bool, yield func(interval Interval[Key], valuesIndex int) bool) bool
+
+	// collect walks the node, passing every stored interval, its value
+	// index, and the bounded flags it was stored with to emit.
+	collect(emit func(interval Interval[Key], valuesIndex int, boundedStart, boundedEnd bool))
 }
 
 // hierarchicalNode is a node that has several children nodes, bucketed by the
 // index.
-type hierarchicalNode struct {
-	Children []node
+type hierarchicalNode[Key any] struct {
+	Children []node[Key]
+
+	// Population is the number of (interval, valuesIndex) routings into this
+	// node's direct children: an entry routed into three buckets counts three
+	// times. [hierarchicalNode.collect] can emit more than this, since a
+	// hierarchical child may itself hold one entry in several of its own
+	// buckets, so Population is a lower bound on that count. It is kept
+	// incrementally so [hierarchicalNode.collapse] can tell a node is too
+	// populous to collapse without walking its subtree.
+	Population int
 }
 
 // newHierarchicalNode creates a new hierarchical node.
-func newHierarchicalNode() *hierarchicalNode {
-	node := hierarchicalNode{
-		Children: make([]node, hierarchicalFanout),
+func newHierarchicalNode[Key any]() *hierarchicalNode[Key] {
+	node := hierarchicalNode[Key]{
+		Children: make([]node[Key], hierarchicalFanout),
 	}
 
 	return &node
 }
 
 // Ensure that hierarchicalNode implements the [node] interface.
-var _ node = &hierarchicalNode{}
+var _ node[uint64] = &hierarchicalNode[uint64]{}
 
 // leafNode is a node that stores the intervals directly.
-type leafNode struct {
+type leafNode[Key any] struct {
 	Indices   []int
-	Intervals []Interval
+	Intervals []Interval[Key]
+
+	// BoundedStart and BoundedEnd record, per entry, whether Start/End were
+	// still constraining which bucket it was routed through when it landed
+	// here (see the [node] doc comment). shouldSplit and a later re-split
+	// both need this to route an entry correctly once a side has gone
+	// unbounded: recomputing a bucket from its real Start/End at that point
+	// would use a nibble that has nothing to do with the entry's placement.
+	BoundedStart []bool
+	BoundedEnd   []bool
 }
 
-var _ node = &leafNode{}
+var _ node[uint64] = &leafNode[uint64]{}
 
 // Add inserts a new interval into the interval tree.
-func (h *hierarchicalNode) Add(interval Interval, valuesIndex int) node {
-	// Indices are split into two parts:
-	//
-	// MSB Bits:  0123    4567 ...
-	//           Bucket  Offset...
-	//
-	// Therefore we shift down by 64 - 4 to get the bucket index.
-	startBucketIndex := interval.Start >> (64 - branchingFactorPower)
-	endBucketIndex := interval.End >> (64 - branchingFactorPower)
+func (h *hierarchicalNode[Key]) Add(bucketer Bucketer[Key], interval Interval[Key], valuesIndex int, depth int, boundedStart, boundedEnd bool) node[Key] {
+	startBucketIndex := 0
+	if boundedStart {
+		startBucketIndex = bucketer.Bucket(interval.Start, depth)
+	}
 
-	newInterval := Interval{
-		Start: interval.Start << branchingFactorPower,
-		End:   math.MaxUint64,
+	endBucketIndex := hierarchicalFanout - 1
+	if boundedEnd {
+		endBucketIndex = bucketer.Bucket(interval.End, depth)
 	}
 
 	for i := startBucketIndex; i <= endBucketIndex; i++ {
-		if i > startBucketIndex {
-			newInterval.Start = 0
-		}
-
-		if i == endBucketIndex {
-			newInterval.End = interval.End << branchingFactorPower
-		}
-
 		if h.Children[i] == nil {
-			h.Children[i] = &leafNode{}
+			h.Children[i] = &leafNode[Key]{}
 		}
 
-		h.Children[i] = h.Children[i].Add(newInterval, valuesIndex)
+		childBoundedStart := boundedStart && i == startBucketIndex
+		childBoundedEnd := boundedEnd && i == endBucketIndex
+
+		h.Children[i] = h.Children[i].Add(bucketer, interval, valuesIndex, depth+1, childBoundedStart, childBoundedEnd)
+		h.Population++
 	}
 
 	return h
 }
 
-// AllIntersections returns all values in the interval tree that intersect with
-// the given interval.
-func (h hierarchicalNode) AllIntersections(start uint64, end uint64) valueIndices {
-	// Indices are split into two parts:
-	//
-	// MSB Bits:  0123    4567 ...
-	//           Bucket  Offset...
-	//
-	// Therefore we shift down by 64 - 4 to get the bucket index.
-	startBucketIndex := start >> (64 - branchingFactorPower)
-	endBucketIndex := end >> (64 - branchingFactorPower)
+// Remove deletes the (interval, valuesIndex) pair for which matches reports
+// true from every bucket interval routes into, collapsing back into a leaf
+// once the surviving population is sparse enough.
+func (h *hierarchicalNode[Key]) Remove(bucketer Bucketer[Key], interval Interval[Key], matches func(valuesIndex int) bool, depth int, boundedStart, boundedEnd bool) (node[Key], bool) {
+	startBucketIndex := 0
+	if boundedStart {
+		startBucketIndex = bucketer.Bucket(interval.Start, depth)
+	}
 
-	matchingIndices := make(valueIndices)
+	endBucketIndex := hierarchicalFanout - 1
+	if boundedEnd {
+		endBucketIndex = bucketer.Bucket(interval.End, depth)
+	}
 
-	// The new "offset" indices to search for within the bucket.
-	//
-	// | Bucket 0 | Bucket 1 | Bucket 2 | ...
-	//     ^--------------------^
-	//   start                 end
-	//
-	// This start bucket offset is only valid for the first bucket, as other
-	// buckets should be searched from the very beginning.
-	var (
-		bucketOffsetStart uint64 = start << branchingFactorPower
-		bucketOffsetEnd   uint64 = math.MaxUint64
-	)
+	removed := false
 
 	for i := startBucketIndex; i <= endBucketIndex; i++ {
-		if i > startBucketIndex {
-			bucketOffsetStart = 0
+		if h.Children[i] == nil {
+			continue
 		}
 
-		// If we're at the last bucket, we need to set the end offset to the
-		// end of the interval.
-		if i == endBucketIndex {
-			bucketOffsetEnd = end << branchingFactorPower
+		childBoundedStart := boundedStart && i == startBucketIndex
+		childBoundedEnd := boundedEnd && i == endBucketIndex
+
+		child, childRemoved := h.Children[i].Remove(bucketer, interval, matches, depth+1, childBoundedStart, childBoundedEnd)
+		if childRemoved {
+			removed = true
+			h.Population--
 		}
 
+		h.Children[i] = child
+	}
+
+	if !removed {
+		return h, false
+	}
+
+	if collapsed, ok := h.collapse(bucketer, depth); ok {
+		return collapsed, true
+	}
+
+	return h, true
+}
+
+// collapse converts h back into a leaf node if its surviving population has
+// fallen below maxLeafFanout and would all route into the same bucket at
+// depth - the mirror image of [leafNode.shouldSplit]. It returns the
+// replacement node, and whether collapsing occurred.
+//
+// Population is checked before walking the subtree at all: a node near the
+// root of a large tree is collapse-checked on every Remove along its path,
+// so bailing out on population alone keeps that O(depth) rather than O(n).
+func (h *hierarchicalNode[Key]) collapse(bucketer Bucketer[Key], depth int) (node[Key], bool) {
+	if h.Population >= maxLeafFanout {
+		return h, false
+	}
+
+	leaf := &leafNode[Key]{}
+
+	h.collect(func(interval Interval[Key], valuesIndex int, boundedStart, boundedEnd bool) {
+		leaf.Intervals = append(leaf.Intervals, interval)
+		leaf.Indices = append(leaf.Indices, valuesIndex)
+		leaf.BoundedStart = append(leaf.BoundedStart, boundedStart)
+		leaf.BoundedEnd = append(leaf.BoundedEnd, boundedEnd)
+	})
+
+	if leaf.shouldSplit(bucketer, depth) {
+		return h, false
+	}
+
+	return leaf, true
+}
+
+// Intersections calls yield for every (interval, valuesIndex) pair stored
+// beneath this hierarchical node that overlaps [start, end].
+func (h *hierarchicalNode[Key]) Intersections(bucketer Bucketer[Key], start, end Key, depth int, boundedStart, boundedEnd bool, yield func(interval Interval[Key], valuesIndex int) bool) bool {
+	startBucketIndex := 0
+	if boundedStart {
+		startBucketIndex = bucketer.Bucket(start, depth)
+	}
+
+	endBucketIndex := hierarchicalFanout - 1
+	if boundedEnd {
+		endBucketIndex = bucketer.Bucket(end, depth)
+	}
+
+	for i := startBucketIndex; i <= endBucketIndex; i++ {
 		// If the bucket is nil, then there are no intervals in this bucket.
 		if h.Children[i] == nil {
 			continue
 		}
 
-		intersections := h.Children[i].AllIntersections(bucketOffsetStart, bucketOffsetEnd)
+		childBoundedStart := boundedStart && i == startBucketIndex
+		childBoundedEnd := boundedEnd && i == endBucketIndex
 
-		if len(intersections) > 0 {
-			matchingIndices.Merge(intersections)
+		if !h.Children[i].Intersections(bucketer, start, end, depth+1, childBoundedStart, childBoundedEnd, yield) {
+			return false
 		}
 	}
 
-	return matchingIndices
+	return true
+}
+
+// collect passes every interval stored beneath this hierarchical node to
+// emit.
+func (h *hierarchicalNode[Key]) collect(emit func(interval Interval[Key], valuesIndex int, boundedStart, boundedEnd bool)) {
+	for _, child := range h.Children {
+		if child == nil {
+			continue
+		}
+
+		child.collect(emit)
+	}
 }
 
 // Add inserts a new interval into the interval tree.
-func (l *leafNode) Add(interval Interval, valuesIndex int) node {
+func (l *leafNode[Key]) Add(bucketer Bucketer[Key], interval Interval[Key], valuesIndex int, depth int, boundedStart, boundedEnd bool) node[Key] {
 	// If this leaf node is full, we need to convert it into a hierarchical
 	// node.
 	//
@@ -252,38 +429,78 @@ func (l *leafNode) Add(interval Interval, valuesIndex int) node {
 	// growth.
 	if len(l.Intervals) > 0 &&
 		len(l.Intervals)%maxLeafFanout == 0 &&
-		l.shouldSplit() {
-		h := newHierarchicalNode()
+		l.shouldSplit(bucketer, depth) {
+		h := newHierarchicalNode[Key]()
 
-		for i, interval := range l.Intervals {
-			h.Add(interval, l.Indices[i])
+		for i, storedInterval := range l.Intervals {
+			h.Add(bucketer, storedInterval, l.Indices[i], depth, l.BoundedStart[i], l.BoundedEnd[i])
 		}
 
-		return h.Add(interval, valuesIndex)
+		return h.Add(bucketer, interval, valuesIndex, depth, boundedStart, boundedEnd)
 	}
 
 	l.Intervals = append(l.Intervals, interval)
 	l.Indices = append(l.Indices, valuesIndex)
+	l.BoundedStart = append(l.BoundedStart, boundedStart)
+	l.BoundedEnd = append(l.BoundedEnd, boundedEnd)
 
 	return l
 }
 
+// Remove deletes the first stored (interval, valuesIndex) pair for which
+// both the interval matches exactly and matches reports true. It reports the
+// replacement node - nil if the leaf became empty - and whether an entry was
+// removed.
+func (l *leafNode[Key]) Remove(bucketer Bucketer[Key], interval Interval[Key], matches func(valuesIndex int) bool, _ int, _, _ bool) (node[Key], bool) {
+	for i, stored := range l.Intervals {
+		if bucketer.Compare(stored.Start, interval.Start) != 0 || bucketer.Compare(stored.End, interval.End) != 0 {
+			continue
+		}
+
+		if !matches(l.Indices[i]) {
+			continue
+		}
+
+		l.Intervals = append(l.Intervals[:i], l.Intervals[i+1:]...)
+		l.Indices = append(l.Indices[:i], l.Indices[i+1:]...)
+		l.BoundedStart = append(l.BoundedStart[:i], l.BoundedStart[i+1:]...)
+		l.BoundedEnd = append(l.BoundedEnd[:i], l.BoundedEnd[i+1:]...)
+
+		if len(l.Intervals) == 0 {
+			return nil, true
+		}
+
+		return l, true
+	}
+
+	return l, false
+}
+
 // shouldSplit returns whether the leaf node should be split.
 //
-// This checks if there would be any benefit to splitting the leaf node.
-func (l *leafNode) shouldSplit() bool {
-	hierarchicalStartOffsetCount := make(map[uint64]int, len(l.Intervals))
-	hierarchicalEndOffsetCount := make(map[uint64]int, len(l.Intervals))
+// This checks if there would be any benefit to splitting the leaf node: i.e.
+// whether bucketer would actually route the stored intervals into different
+// buckets at depth. An entry with BoundedStart/BoundedEnd false is not
+// counted toward any bucket on that side, since it no longer routes by its
+// real Start/End (see the [node] doc comment). Every entry still counts
+// toward the total that each bucket's count is compared against, so on a
+// side where some entries are bounded and others are not, shouldSplit always
+// reports true.
+func (l *leafNode[Key]) shouldSplit(bucketer Bucketer[Key], depth int) bool {
+	startBucketCounts := make(map[int]int, len(l.Intervals))
+	endBucketCounts := make(map[int]int, len(l.Intervals))
 
-	for _, interval := range l.Intervals {
-		allButLastOffsetStart := interval.Start & bucketMask
-		allButLastOffsetEnd := interval.End & bucketMask
+	for i, interval := range l.Intervals {
+		if l.BoundedStart[i] {
+			startBucketCounts[bucketer.Bucket(interval.Start, depth)]++
+		}
 
-		hierarchicalStartOffsetCount[allButLastOffsetStart]++
-		hierarchicalEndOffsetCount[allButLastOffsetEnd]++
+		if l.BoundedEnd[i] {
+			endBucketCounts[bucketer.Bucket(interval.End, depth)]++
+		}
 	}
 
-	for _, count := range hierarchicalStartOffsetCount {
+	for _, count := range startBucketCounts {
 		// If any bucket contains some intervals, but not all of them, then we
 		// should split the leaf node.
 		if count != 0 && count != len(l.Intervals) {
@@ -291,7 +508,7 @@ func (l *leafNode) shouldSplit() bool {
 		}
 	}
 
-	for _, count := range hierarchicalEndOffsetCount {
+	for _, count := range endBucketCounts {
 		// If any bucket contains some intervals, but not all of them, then we
 		// should split the leaf node.
 		if count != 0 && count != len(l.Intervals) {
@@ -302,32 +519,31 @@ func (l *leafNode) shouldSplit() bool {
 	return false
 }
 
-// AllIntersections returns all values in the interval tree that intersect with
-// the given interval.
-//
-// This node is a leaf node, so it requires a linear scan of the values.
-func (l leafNode) AllIntersections(start uint64, end uint64) valueIndices {
-	// Optimize for the case where we're looking for all intervals in this
-	// bucket.
-	if start == 0 && end == math.MaxUint64 {
-		valueIndices := make(valueIndices, len(l.Intervals))
-
-		for i := range l.Intervals {
-			valueIndices[l.Indices[i]] = struct{}{}
-		}
-
-		return valueIndices
+// collect passes every interval stored in this leaf, and the bounded flags it
+// was stored with, to emit.
+func (l *leafNode[Key]) collect(emit func(interval Interval[Key], valuesIndex int, boundedStart, boundedEnd bool)) {
+	for i, interval := range l.Intervals {
+		emit(interval, l.Indices[i], l.BoundedStart[i], l.BoundedEnd[i])
 	}
+}
 
-	matchingIndices := make(valueIndices, len(l.Intervals))
-
+// Intersections calls yield for every (interval, valuesIndex) pair stored in
+// this leaf that overlaps [start, end].
+//
+// This node is a leaf node, so it requires a linear scan of the values. The
+// comparison always uses the real start/end - whether they were still
+// constraining routing on the way down doesn't change whether the stored
+// interval truly overlaps the query.
+func (l *leafNode[Key]) Intersections(bucketer Bucketer[Key], start, end Key, _ int, _, _ bool, yield func(interval Interval[Key], valuesIndex int) bool) bool {
 	for i, interval := range l.Intervals {
-		if end < interval.Start || start > interval.End {
+		if bucketer.Compare(end, interval.Start) < 0 || bucketer.Compare(start, interval.End) > 0 {
 			continue
 		}
 
-		matchingIndices[l.Indices[i]] = struct{}{}
+		if !yield(interval, l.Indices[i]) {
+			return false
+		}
 	}
 
-	return matchingIndices
+	return true
 }
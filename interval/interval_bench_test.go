@@ -0,0 +1,76 @@
+package interval_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/crystalix007/multi-binned-intervals/interval"
+)
+
+// randomIntervalTree builds a tree of n random intervals, each up to maxSpan
+// wide, for use as benchmark fixtures.
+func randomIntervalTree(n int, maxSpan uint64) interval.Tree[uint64, int] {
+	tree := interval.New[int]()
+
+	for i := range n {
+		start := rand.Uint64()
+		end := start + rand.Uint64N(maxSpan)
+
+		tree.Add(interval.Interval[uint64]{Start: start, End: end}, i)
+	}
+
+	return tree
+}
+
+func BenchmarkTree_AllIntersections(b *testing.B) {
+	const intervalCount = 1_000_000
+
+	tree := randomIntervalTree(intervalCount, 1<<40)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		start := rand.Uint64()
+
+		tree.AllIntersections(start, start+(1<<40))
+	}
+}
+
+func BenchmarkStaticTree_AllIntersections(b *testing.B) {
+	const intervalCount = 1_000_000
+
+	tree := randomIntervalTree(intervalCount, 1<<40)
+	staticTree := tree.Freeze()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		start := rand.Uint64()
+
+		staticTree.AllIntersections(start, start+(1<<40))
+	}
+}
+
+func BenchmarkSegmentTree_Stab(b *testing.B) {
+	const intervalCount = 1_000_000
+
+	intervals := make([]interval.Interval[uint64], intervalCount)
+	values := make([]int, intervalCount)
+
+	for i := range intervalCount {
+		start := rand.Uint64()
+		end := start + rand.Uint64N(1<<40)
+
+		intervals[i] = interval.Interval[uint64]{Start: start, End: end}
+		values[i] = i
+	}
+
+	segmentTree := interval.NewSegmentTree[int]()
+	segmentTree.Build(intervals, values)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		segmentTree.Stab(rand.Uint64())
+	}
+}
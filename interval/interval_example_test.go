@@ -10,9 +10,9 @@ import (
 func Example() {
 	intervals := interval.New[string]()
 
-	intervals.Add(interval.Interval{1, 5}, "first")
-	intervals.Add(interval.Interval{7, 10}, "second")
-	intervals.Add(interval.Interval{1, 2}, "third")
+	intervals.Add(interval.Interval[uint64]{Start: 1, End: 5}, "first")
+	intervals.Add(interval.Interval[uint64]{Start: 7, End: 10}, "second")
+	intervals.Add(interval.Interval[uint64]{Start: 1, End: 2}, "third")
 
 	intersections, ok := intervals.AllIntersections(5, 8)
 
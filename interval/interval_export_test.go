@@ -15,19 +15,21 @@ const (
 	HierarchicalFanout = hierarchicalFanout
 )
 
-// Node reexports the internal [node] type.
-type Node = node
-
-// ValueIndices reexports the internal [valueIndices] type.
-type ValueIndices = valueIndices
-
-// HierarchicalNode reexports the internal [hierarchicalNode] type.
-type HierarchicalNode = hierarchicalNode
-
-// LeafNode reexports the internal [leafNode] type.
-type LeafNode = leafNode
-
-// ShouldSplit reexports the internal [shouldSplit] method.
-func (l *LeafNode) ShouldSplit() bool {
-	return l.shouldSplit()
+// Node reexports the internal [node] type, instantiated over uint64 keys
+// (the only key type the existing white-box tests exercise).
+type Node = node[uint64]
+
+// HierarchicalNode reexports the internal [hierarchicalNode] type,
+// instantiated over uint64 keys.
+type HierarchicalNode = hierarchicalNode[uint64]
+
+// LeafNode reexports the internal [leafNode] type, instantiated over uint64
+// keys.
+type LeafNode = leafNode[uint64]
+
+// ShouldSplit reexports the internal [leafNode.shouldSplit] method. It's a
+// free function, rather than a method on [LeafNode], because Go doesn't
+// allow defining new methods on an alias to an instantiated generic type.
+func ShouldSplit(l *LeafNode, bucketer Bucketer[uint64], depth int) bool {
+	return l.shouldSplit(bucketer, depth)
 }
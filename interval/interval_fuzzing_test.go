@@ -16,7 +16,7 @@ func FuzzTree(f *testing.F) {
 		intervalEndpoint1 uint64,
 		intervalEndpoint2 uint64,
 	) {
-		intervals := make([]interval.Interval, intervalCount)
+		intervals := make([]interval.Interval[uint64], intervalCount)
 		values := make([]uint64, intervalCount)
 
 		intervalBegin, intervalEnd := sortInterval(intervalEndpoint1, intervalEndpoint2)
@@ -29,7 +29,7 @@ func FuzzTree(f *testing.F) {
 
 			valueIntervalBegin, valueIntervalEnd := sortInterval(valueIntervalEndpoint1, valueIntervalEndpoint2)
 
-			intervals[i] = interval.Interval{
+			intervals[i] = interval.Interval[uint64]{
 				Start: valueIntervalBegin,
 				End:   valueIntervalEnd,
 			}
@@ -57,7 +57,43 @@ func FuzzTree(f *testing.F) {
 	})
 }
 
-func getExpectedIntersections(intervalBegin, intervalEnd uint64, intervals []interval.Interval) []uint64 {
+func FuzzSegmentTree(f *testing.F) {
+	f.Fuzz(func(t *testing.T, intervalCount uint16, point uint64) {
+		intervals := make([]interval.Interval[uint64], intervalCount)
+		values := make([]uint64, intervalCount)
+
+		for i := range intervalCount {
+			values[i] = rand.Uint64()
+
+			valueIntervalEndpoint1 := rand.Uint64()
+			valueIntervalEndpoint2 := rand.Uint64()
+
+			valueIntervalBegin, valueIntervalEnd := sortInterval(valueIntervalEndpoint1, valueIntervalEndpoint2)
+
+			intervals[i] = interval.Interval[uint64]{
+				Start: valueIntervalBegin,
+				End:   valueIntervalEnd,
+			}
+		}
+
+		segmentTree := interval.NewSegmentTree[uint64]()
+		segmentTree.Build(intervals, values)
+
+		stabbedValues := segmentTree.Stab(point)
+
+		expectedIntersections := getExpectedIntersections(point, point, intervals)
+
+		expectedValues := make([]uint64, len(expectedIntersections))
+
+		for i, intersection := range expectedIntersections {
+			expectedValues[i] = values[intersection]
+		}
+
+		require.ElementsMatch(t, expectedValues, stabbedValues)
+	})
+}
+
+func getExpectedIntersections(intervalBegin, intervalEnd uint64, intervals []interval.Interval[uint64]) []uint64 {
 	intersections := make([]uint64, 0, len(intervals))
 
 	for i, interval := range intervals {
@@ -15,32 +15,36 @@ func TestLeafNode_ShouldSplit_unsplittable(t *testing.T) {
 
 	leaf := interval.LeafNode{
 		Indices: []int{0},
-		Intervals: []interval.Interval{
+		Intervals: []interval.Interval[uint64]{
 			{Start: 0, End: 0},
 		},
+		BoundedStart: []bool{true},
+		BoundedEnd:   []bool{true},
 	}
 
 	// Add more intervals than the predicate to split, but with indices that
 	// cannot be split efficiently.
 	for i := range interval.MaxLeafFanout {
-		leaf.Add(interval.Interval{Start: uint64(1), End: uint64(1)}, 1+i)
+		leaf.Add(interval.Uint64Bucketer{}, interval.Interval[uint64]{Start: uint64(1), End: uint64(1)}, 1+i, 0, true, true)
 	}
 
-	require.False(t, leaf.ShouldSplit())
+	require.False(t, interval.ShouldSplit(&leaf, interval.Uint64Bucketer{}, 0))
 }
 
 func TestLeafNode_ShouldSplit_splittable(t *testing.T) {
 	t.Parallel()
 
 	// Create a leaf node with 16 intervals that can be split across different
-	// buckets (i.e. are different in more than their last 4 bits).
+	// buckets (i.e. fall into different top-level buckets).
 	var leaf interval.LeafNode
 
+	subBucketSize := uint64(1) << (64 - interval.BranchingFactorPower)
+
 	for i := range interval.MaxLeafFanout {
-		leaf.Add(interval.Interval{Start: uint64(i), End: uint64(i + 1)}, i)
+		leaf.Add(interval.Uint64Bucketer{}, interval.Interval[uint64]{Start: uint64(i) * subBucketSize, End: uint64(i)*subBucketSize + 1}, i, 0, true, true)
 	}
 
-	require.True(t, leaf.ShouldSplit())
+	require.True(t, interval.ShouldSplit(&leaf, interval.Uint64Bucketer{}, 0))
 }
 
 func TestLeafNode_Add_splitting(t *testing.T) {
@@ -52,31 +56,36 @@ func TestLeafNode_Add_splitting(t *testing.T) {
 	subBucketSize := 1 << (64 - interval.BranchingFactorPower)
 
 	for i := range interval.MaxLeafFanout {
-		leaf = leaf.Add(interval.Interval{Start: uint64(i * subBucketSize), End: uint64(i * subBucketSize)}, i)
+		leaf = leaf.Add(interval.Uint64Bucketer{}, interval.Interval[uint64]{Start: uint64(i * subBucketSize), End: uint64(i * subBucketSize)}, i, 0, true, true)
 	}
 
 	var expectedLeafNode interval.LeafNode
 
 	for i := range interval.MaxLeafFanout {
 		expectedLeafNode.Indices = append(expectedLeafNode.Indices, i)
-		expectedLeafNode.Intervals = append(expectedLeafNode.Intervals, interval.Interval{Start: uint64(i * subBucketSize), End: uint64(i * subBucketSize)})
+		expectedLeafNode.Intervals = append(expectedLeafNode.Intervals, interval.Interval[uint64]{Start: uint64(i * subBucketSize), End: uint64(i * subBucketSize)})
+		expectedLeafNode.BoundedStart = append(expectedLeafNode.BoundedStart, true)
+		expectedLeafNode.BoundedEnd = append(expectedLeafNode.BoundedEnd, true)
 	}
 
 	require.Equal(t, &expectedLeafNode, leaf)
 
 	// Add one more interval to trigger the split.
-	leaf = leaf.Add(interval.Interval{Start: 1, End: 1}, interval.MaxLeafFanout)
+	leaf = leaf.Add(interval.Uint64Bucketer{}, interval.Interval[uint64]{Start: 1, End: 1}, interval.MaxLeafFanout, 0, true, true)
 
 	expectedHierarchicalNode := interval.HierarchicalNode{
-		Children: make([]interval.Node, interval.HierarchicalFanout),
+		Children:   make([]interval.Node, interval.HierarchicalFanout),
+		Population: interval.MaxLeafFanout + 1,
 	}
 
 	// The leaf node should have been converted into a hierarchical node, but
 	// the new leaf nodes should be the first child with the same elements.
 	for i := range interval.MaxLeafFanout {
 		expectedNewLeafNode := interval.LeafNode{
-			Indices:   []int{i},
-			Intervals: []interval.Interval{{Start: 0, End: 0}},
+			Indices:      []int{i},
+			Intervals:    []interval.Interval[uint64]{{Start: uint64(i * subBucketSize), End: uint64(i * subBucketSize)}},
+			BoundedStart: []bool{true},
+			BoundedEnd:   []bool{true},
 		}
 
 		expectedHierarchicalNode.Children[i] = &expectedNewLeafNode
@@ -85,10 +94,9 @@ func TestLeafNode_Add_splitting(t *testing.T) {
 	// Now we also need to add the final interval.
 	firstLeafNode := expectedHierarchicalNode.Children[0].(*interval.LeafNode)
 	firstLeafNode.Indices = append(firstLeafNode.Indices, interval.MaxLeafFanout)
-
-	// Has to be a new interval, because the previous one was split, and this
-	// has thus been left-shifted by [interval.BranchingFactorPower] bits.
-	firstLeafNode.Intervals = append(firstLeafNode.Intervals, interval.Interval{Start: interval.HierarchicalFanout, End: interval.HierarchicalFanout})
+	firstLeafNode.Intervals = append(firstLeafNode.Intervals, interval.Interval[uint64]{Start: 1, End: 1})
+	firstLeafNode.BoundedStart = append(firstLeafNode.BoundedStart, true)
+	firstLeafNode.BoundedEnd = append(firstLeafNode.BoundedEnd, true)
 
 	require.Equal(t, &expectedHierarchicalNode, leaf)
 }
@@ -99,9 +107,9 @@ func TestTree(t *testing.T) {
 	// Add some values.
 	tree := interval.New[string]()
 
-	tree.Add(interval.Interval{Start: 0, End: 10}, "a")
-	tree.Add(interval.Interval{Start: 3000, End: (math.MaxUint64 / 16) * 2}, "b")
-	tree.Add(interval.Interval{Start: math.MaxUint64 - 16, End: math.MaxUint64}, "c")
+	tree.Add(interval.Interval[uint64]{Start: 0, End: 10}, "a")
+	tree.Add(interval.Interval[uint64]{Start: 3000, End: (math.MaxUint64 / 16) * 2}, "b")
+	tree.Add(interval.Interval[uint64]{Start: math.MaxUint64 - 16, End: math.MaxUint64}, "c")
 
 	// Check the intersections.
 
@@ -157,7 +165,7 @@ func TestTree_resizing(t *testing.T) {
 	tree := interval.New[int]()
 
 	for i := 0; i < 100; i++ {
-		tree.Add(interval.Interval{Start: uint64(i), End: uint64(i + 1)}, i)
+		tree.Add(interval.Interval[uint64]{Start: uint64(i), End: uint64(i + 1)}, i)
 	}
 
 	intersections, ok := tree.AllIntersections(0, 1600)
@@ -169,3 +177,161 @@ func TestTree_resizing(t *testing.T) {
 
 	require.Len(t, intersections, 100)
 }
+
+func TestTree_Remove(t *testing.T) {
+	t.Parallel()
+
+	tree := interval.New[string]()
+
+	tree.Add(interval.Interval[uint64]{Start: 0, End: 10}, "a")
+	tree.Add(interval.Interval[uint64]{Start: 5, End: 15}, "b")
+
+	require.False(t, tree.Remove(interval.Interval[uint64]{Start: 0, End: 10}, "nonexistent"))
+	require.True(t, tree.Remove(interval.Interval[uint64]{Start: 0, End: 10}, "a"))
+
+	intersections, ok := tree.AllIntersections(0, 10)
+
+	require.True(t, ok)
+	require.Equal(t, []string{"b"}, intersections)
+
+	// Removing the same entry a second time should fail.
+	require.False(t, tree.Remove(interval.Interval[uint64]{Start: 0, End: 10}, "a"))
+}
+
+func TestTree_Remove_collapsesHierarchicalNode(t *testing.T) {
+	t.Parallel()
+
+	tree := interval.New[int]()
+
+	subBucketSize := uint64(1) << (64 - interval.BranchingFactorPower)
+
+	// The root starts out as a hierarchical node. Add more than MaxLeafFanout
+	// intervals to it, one per top-level bucket (the last wraps around to
+	// bucket 0), so that it is too populous to collapse.
+	for i := range interval.MaxLeafFanout + 1 {
+		start := uint64(i) * subBucketSize
+
+		tree.Add(interval.Interval[uint64]{Start: start, End: start}, i)
+	}
+
+	// Remove all but one of them, which should collapse the root into a leaf.
+	for i := 1; i < interval.MaxLeafFanout+1; i++ {
+		start := uint64(i) * subBucketSize
+
+		require.True(t, tree.Remove(interval.Interval[uint64]{Start: start, End: start}, i))
+	}
+
+	intersections, ok := tree.AllIntersections(0, 0)
+
+	require.True(t, ok)
+	require.Equal(t, []int{0}, intersections)
+}
+
+// TestTree_Add_straddlesMiddleBucket guards against an interval that fully
+// spans one of a hierarchical node's buckets going missing: once that bucket
+// has itself split into a hierarchicalNode, naively re-deriving a start/end
+// bucket range from the straddling interval's own Start/End at the deeper
+// depth can yield an empty range and drop the interval entirely.
+func TestTree_Add_straddlesMiddleBucket(t *testing.T) {
+	t.Parallel()
+
+	tree := interval.New[string]()
+
+	subBucketSize := uint64(1) << (64 - interval.BranchingFactorPower)
+
+	subSubBucketSize := subBucketSize >> interval.BranchingFactorPower
+
+	// Force bucket 2 to split into a hierarchical node of its own, by
+	// spreading its points across the sub-buckets one depth down.
+	for i := range interval.MaxLeafFanout + 1 {
+		start := 2*subBucketSize + uint64(i%interval.HierarchicalFanout)*subSubBucketSize
+
+		tree.Add(interval.Interval[uint64]{Start: start, End: start}, "bucket2")
+	}
+
+	// This interval starts in bucket 1 and ends in bucket 3, so bucket 2 is a
+	// "middle" bucket it straddles entirely.
+	tree.Add(interval.Interval[uint64]{Start: 1 * subBucketSize, End: 3 * subBucketSize}, "wide")
+
+	for _, point := range []uint64{2 * subBucketSize, 3*subBucketSize - 1} {
+		intersections, ok := tree.AllIntersections(point, point)
+
+		require.True(t, ok, "point %#x", point)
+		require.Contains(t, intersections, "wide", "point %#x", point)
+	}
+}
+
+func TestTree_Update(t *testing.T) {
+	t.Parallel()
+
+	tree := interval.New[string]()
+
+	tree.Add(interval.Interval[uint64]{Start: 0, End: 10}, "a")
+
+	require.False(t, tree.Update(interval.Interval[uint64]{Start: 0, End: 10}, "nonexistent", "c"))
+	require.True(t, tree.Update(interval.Interval[uint64]{Start: 0, End: 10}, "a", "b"))
+
+	intersections, ok := tree.AllIntersections(0, 10)
+
+	require.True(t, ok)
+	require.Equal(t, []string{"b"}, intersections)
+}
+
+func TestTree_Intersections(t *testing.T) {
+	t.Parallel()
+
+	tree := interval.New[string]()
+
+	tree.Add(interval.Interval[uint64]{Start: 0, End: 10}, "a")
+	tree.Add(interval.Interval[uint64]{Start: 7, End: 20}, "b")
+	tree.Add(interval.Interval[uint64]{Start: 100, End: 200}, "c")
+
+	var values []string
+
+	for _, value := range tree.Intersections(5, 15) {
+		values = append(values, value)
+	}
+
+	slices.Sort(values)
+
+	require.Equal(t, []string{"a", "b"}, values)
+}
+
+func TestTree_Intersections_stopsEarly(t *testing.T) {
+	t.Parallel()
+
+	tree := interval.New[string]()
+
+	tree.Add(interval.Interval[uint64]{Start: 0, End: 10}, "a")
+	tree.Add(interval.Interval[uint64]{Start: 0, End: 10}, "b")
+
+	seen := 0
+
+	for range tree.Intersections(0, 10) {
+		seen++
+
+		break
+	}
+
+	require.Equal(t, 1, seen)
+}
+
+func TestTree_Stab(t *testing.T) {
+	t.Parallel()
+
+	tree := interval.New[string]()
+
+	tree.Add(interval.Interval[uint64]{Start: 0, End: 10}, "a")
+	tree.Add(interval.Interval[uint64]{Start: 5, End: 15}, "b")
+	tree.Add(interval.Interval[uint64]{Start: 20, End: 30}, "c")
+
+	var values []string
+
+	for _, value := range tree.Stab(7) {
+		values = append(values, value)
+	}
+
+	slices.Sort(values)
+
+	require.Equal(t, []string{"a", "b"}, values)
+}
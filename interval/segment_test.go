@@ -0,0 +1,72 @@
+package interval_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/crystalix007/multi-binned-intervals/interval"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentTree_Stab(t *testing.T) {
+	t.Parallel()
+
+	segmentTree := interval.NewSegmentTree[string]()
+
+	segmentTree.Build([]interval.Interval[uint64]{
+		{Start: 0, End: 10},
+		{Start: 5, End: 15},
+		{Start: 20, End: 30},
+	}, []string{"a", "b", "c"})
+
+	t.Run("OverlapOfTwo", func(t *testing.T) {
+		t.Parallel()
+
+		values := segmentTree.Stab(7)
+
+		slices.Sort(values)
+
+		require.Equal(t, []string{"a", "b"}, values)
+	})
+
+	t.Run("SingleMatch", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, []string{"c"}, segmentTree.Stab(25))
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		t.Parallel()
+
+		require.Nil(t, segmentTree.Stab(17))
+	})
+
+	t.Run("AfterLastInterval", func(t *testing.T) {
+		t.Parallel()
+
+		require.Nil(t, segmentTree.Stab(math.MaxUint64))
+	})
+}
+
+func TestSegmentTree_Stab_unboundedInterval(t *testing.T) {
+	t.Parallel()
+
+	segmentTree := interval.NewSegmentTree[string]()
+
+	segmentTree.Build([]interval.Interval[uint64]{
+		{Start: math.MaxUint64 - 10, End: math.MaxUint64},
+	}, []string{"a"})
+
+	require.Equal(t, []string{"a"}, segmentTree.Stab(math.MaxUint64))
+}
+
+func TestSegmentTree_Stab_empty(t *testing.T) {
+	t.Parallel()
+
+	segmentTree := interval.NewSegmentTree[string]()
+
+	segmentTree.Build(nil, nil)
+
+	require.Nil(t, segmentTree.Stab(0))
+}
@@ -0,0 +1,169 @@
+package interval
+
+import (
+	"slices"
+)
+
+// staticEntry is a single slot in a [StaticTree]'s implicit array layout.
+//
+// Entries are stored in Eytzinger order: for a 1-indexed slot k, the left
+// child lives at 2k and the right child at 2k+1, so a node's children can be
+// located without storing any pointers.
+type staticEntry[Key any, Value any] struct {
+	Interval Interval[Key]
+	MaxEnd   Key
+	Value    Value
+}
+
+// StaticTree is an immutable interval index built once from a fully
+// populated [Tree] via [Tree.Freeze].
+//
+// Unlike [Tree], it stores its entries in a single contiguous slice laid out
+// as an implicit (pointerless) interval tree, which is considerably more
+// cache-friendly to query than the hierarchical, pointer-linked tree. It
+// trades that speed for immutability: a [StaticTree] cannot be grown after
+// it is built.
+type StaticTree[Key any, Value any] struct {
+	comparable Comparable[Key]
+
+	// entries is 1-indexed; entries[0] is an unused sentinel so that child
+	// offsets (2k, 2k+1) never need a -1/+1 adjustment.
+	entries []staticEntry[Key, Value]
+}
+
+// staticPair is an (interval, value) tuple awaiting placement into a
+// [StaticTree]'s implicit layout.
+type staticPair[Key any, Value any] struct {
+	Interval Interval[Key]
+	Value    Value
+}
+
+// Freeze builds a [StaticTree] from every interval currently stored in t.
+//
+// The resulting tree is independent of t: later calls to t.Add do not affect
+// it.
+func (t *tree[Key, Value]) Freeze() *StaticTree[Key, Value] {
+	pairs := make([]staticPair[Key, Value], 0, len(t.Values))
+
+	// A single Add call routes one (interval, valuesIndex) into every bucket
+	// its range straddles, so collect walks - and would otherwise emit -
+	// that same pair once per bucket it landed in. Skip the repeats.
+	seen := make(map[int]struct{}, len(t.Values))
+
+	t.Root.collect(func(interval Interval[Key], valuesIndex int, _, _ bool) {
+		if _, ok := seen[valuesIndex]; ok {
+			return
+		}
+
+		seen[valuesIndex] = struct{}{}
+
+		pairs = append(pairs, staticPair[Key, Value]{Interval: interval, Value: t.Values[valuesIndex]})
+	})
+
+	slices.SortFunc(pairs, func(a, b staticPair[Key, Value]) int {
+		return t.Bucketer.Compare(a.Interval.Start, b.Interval.Start)
+	})
+
+	entries := make([]staticEntry[Key, Value], len(pairs)+1)
+
+	layoutEytzinger(entries, pairs, 0, 1)
+	fillMaxEnd(t.Bucketer, entries, 1)
+
+	return &StaticTree[Key, Value]{comparable: t.Bucketer, entries: entries}
+}
+
+// layoutEytzinger recursively copies pairs[i:] into entries, starting the
+// in-order walk at array index i and placing the next visited element at
+// slot k, so that entries ends up in Eytzinger order. It returns the index
+// of the next unconsumed pair.
+func layoutEytzinger[Key any, Value any](entries []staticEntry[Key, Value], pairs []staticPair[Key, Value], i, k int) int {
+	if k >= len(entries) {
+		return i
+	}
+
+	i = layoutEytzinger(entries, pairs, i, 2*k)
+
+	entries[k] = staticEntry[Key, Value]{Interval: pairs[i].Interval, Value: pairs[i].Value}
+	i++
+
+	i = layoutEytzinger(entries, pairs, i, 2*k+1)
+
+	return i
+}
+
+// fillMaxEnd computes, bottom-up, the maximum End of any interval in the
+// subtree rooted at slot k, storing it alongside each entry for query-time
+// pruning. It returns that maximum, and whether slot k holds an entry at all.
+func fillMaxEnd[Key any, Value any](comparable Comparable[Key], entries []staticEntry[Key, Value], k int) (Key, bool) {
+	if k >= len(entries) {
+		var zero Key
+
+		return zero, false
+	}
+
+	maxEnd := entries[k].Interval.End
+
+	if left, ok := fillMaxEnd(comparable, entries, 2*k); ok && comparable.Compare(left, maxEnd) > 0 {
+		maxEnd = left
+	}
+
+	if right, ok := fillMaxEnd(comparable, entries, 2*k+1); ok && comparable.Compare(right, maxEnd) > 0 {
+		maxEnd = right
+	}
+
+	entries[k].MaxEnd = maxEnd
+
+	return maxEnd, true
+}
+
+// AllIntersections returns all values in the static tree that intersect with
+// the given interval.
+func (s *StaticTree[Key, Value]) AllIntersections(start, end Key) ([]Value, bool) {
+	var values []Value
+
+	s.query(1, start, end, func(_ Interval[Key], value Value) bool {
+		values = append(values, value)
+
+		return true
+	})
+
+	return values, len(values) > 0
+}
+
+// Stab returns all values in the static tree whose interval contains point.
+func (s *StaticTree[Key, Value]) Stab(point Key) ([]Value, bool) {
+	return s.AllIntersections(point, point)
+}
+
+// query walks the subtree rooted at slot k, invoking emit for every entry
+// that overlaps [start, end]. It prunes the left subtree when nothing in it
+// can reach far enough to overlap, and the right subtree once the current
+// entry already starts after end (since everything further right starts
+// later still).
+func (s *StaticTree[Key, Value]) query(k int, start, end Key, emit func(interval Interval[Key], value Value) bool) bool {
+	if k >= len(s.entries) {
+		return true
+	}
+
+	entry := &s.entries[k]
+
+	if s.comparable.Compare(start, entry.MaxEnd) > 0 {
+		return true
+	}
+
+	if !s.query(2*k, start, end, emit) {
+		return false
+	}
+
+	if s.comparable.Compare(entry.Interval.Start, end) <= 0 && s.comparable.Compare(start, entry.Interval.End) <= 0 {
+		if !emit(entry.Interval, entry.Value) {
+			return false
+		}
+	}
+
+	if s.comparable.Compare(entry.Interval.Start, end) > 0 {
+		return true
+	}
+
+	return s.query(2*k+1, start, end, emit)
+}
@@ -0,0 +1,148 @@
+package interval_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/crystalix007/multi-binned-intervals/interval"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticTree(t *testing.T) {
+	t.Parallel()
+
+	tree := interval.New[string]()
+
+	tree.Add(interval.Interval[uint64]{Start: 0, End: 10}, "a")
+	tree.Add(interval.Interval[uint64]{Start: 3000, End: (math.MaxUint64 / 16) * 2}, "b")
+	tree.Add(interval.Interval[uint64]{Start: math.MaxUint64 - 16, End: math.MaxUint64}, "c")
+
+	staticTree := tree.Freeze()
+
+	t.Run("EqualToInterval", func(t *testing.T) {
+		t.Parallel()
+
+		intersections, ok := staticTree.AllIntersections(0, 10)
+
+		require.True(t, ok)
+		require.Equal(t, []string{"a"}, intersections)
+	})
+
+	t.Run("OverlappingInterval", func(t *testing.T) {
+		t.Parallel()
+
+		intersections, ok := staticTree.AllIntersections(5, 15)
+
+		require.True(t, ok)
+		require.Equal(t, []string{"a"}, intersections)
+	})
+
+	t.Run("NoIntersectingInterval", func(t *testing.T) {
+		t.Parallel()
+
+		intersections, ok := staticTree.AllIntersections(11, 20)
+
+		require.False(t, ok)
+		require.Nil(t, intersections)
+	})
+
+	t.Run("MaxInt64", func(t *testing.T) {
+		t.Parallel()
+
+		intersections, ok := staticTree.AllIntersections(math.MaxUint64, math.MaxUint64)
+
+		require.True(t, ok)
+		require.Equal(t, []string{"c"}, intersections)
+	})
+
+	t.Run("StraddlingBuckets", func(t *testing.T) {
+		t.Parallel()
+
+		intersections, ok := staticTree.AllIntersections(7, (math.MaxUint64/16)*10)
+
+		require.True(t, ok)
+		require.ElementsMatch(t, []string{"a", "b"}, intersections)
+	})
+
+	t.Run("Stab", func(t *testing.T) {
+		t.Parallel()
+
+		intersections, ok := staticTree.Stab(5)
+
+		require.True(t, ok)
+		require.Equal(t, []string{"a"}, intersections)
+	})
+}
+
+// TestStaticTree_Freeze_wideInterval guards against a single wide interval -
+// one that Add routes into more than one bucket - coming back duplicated
+// from a frozen tree, since Freeze walks the same multi-binned storage that
+// Intersections has to deduplicate.
+func TestStaticTree_Freeze_wideInterval(t *testing.T) {
+	t.Parallel()
+
+	tree := interval.New[string]()
+
+	tree.Add(interval.Interval[uint64]{Start: 0, End: math.MaxUint64}, "a")
+
+	staticTree := tree.Freeze()
+
+	intersections, ok := staticTree.AllIntersections(0, math.MaxUint64)
+
+	require.True(t, ok)
+	require.Equal(t, []string{"a"}, intersections)
+}
+
+func FuzzStaticTree(f *testing.F) {
+	f.Fuzz(func(
+		t *testing.T,
+		intervalCount uint16,
+		intervalEndpoint1 uint64,
+		intervalEndpoint2 uint64,
+	) {
+		intervals := make([]interval.Interval[uint64], intervalCount)
+		values := make([]uint64, intervalCount)
+
+		intervalBegin, intervalEnd := sortInterval(intervalEndpoint1, intervalEndpoint2)
+
+		for i := range intervalCount {
+			values[i] = rand.Uint64()
+
+			valueIntervalEndpoint1 := rand.Uint64()
+			valueIntervalEndpoint2 := rand.Uint64()
+
+			valueIntervalBegin, valueIntervalEnd := sortInterval(valueIntervalEndpoint1, valueIntervalEndpoint2)
+
+			intervals[i] = interval.Interval[uint64]{
+				Start: valueIntervalBegin,
+				End:   valueIntervalEnd,
+			}
+		}
+
+		tree := interval.New[uint64]()
+
+		for i := range intervalCount {
+			tree.Add(intervals[i], values[i])
+		}
+
+		staticTree := tree.Freeze()
+
+		staticIntersectionValues, foundIntersections := staticTree.AllIntersections(intervalBegin, intervalEnd)
+
+		expectedIntersections := getExpectedIntersections(intervalBegin, intervalEnd, intervals)
+
+		require.Equal(t, len(expectedIntersections) > 0, foundIntersections)
+
+		expectedIntersectionValues := make([]uint64, len(expectedIntersections))
+
+		for i, intersection := range expectedIntersections {
+			expectedIntersectionValues[i] = values[intersection]
+		}
+
+		// testify's ElementsMatch compares multisets, so this also catches a
+		// value coming back more than once - e.g. from an interval that
+		// spanned several buckets and wasn't deduplicated while freezing.
+		require.ElementsMatch(t, expectedIntersectionValues, staticIntersectionValues)
+	})
+}